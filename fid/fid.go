@@ -0,0 +1,552 @@
+// Package fid unifies the gofid and idgen ID formats behind a single Spec interface, so downstream
+// services can migrate between them incrementally instead of importing both packages and
+// duplicating validation.
+package fid
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	vendorLength     = 3
+	appTypeLength    = 2
+	locationLength   = 5
+	randLength       = 7
+	delimitChar      = "-"
+	idElements       = 4
+	timeKeyBase      = 36
+	timeKeyLength    = 9
+	unknownLocation  = "MISCR"
+	letterBytes      = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	maxTimestampBase = 101559956668415 // Base 10 representation of the max gofid base36 timestamp
+
+	// idLength is the length of a gofid.v1 ID with no checksum tail extension (i.e. no vendor
+	// secret, or one tagged with the legacy single-character MD5 checksum).
+	idLength = 1 + vendorLength + appTypeLength + appTypeLength + 1 + timeKeyLength + 1 + locationLength + 1 + randLength
+
+	checksumVersionHMACSHA256 = "1"
+	defaultHMACTailLength     = 6
+	base32Alphabet            = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+)
+
+// maxLetterByte is the largest multiple of len(letterBytes) that fits in a byte, used for rejection
+// sampling to avoid modulo bias.
+var maxLetterByte = byte(256 - (256 % len(letterBytes)))
+
+// entropyPool buffers reads from an entropy source and hands out bias-free random characters from
+// letterBytes. It is safe for concurrent use by multiple goroutines. This is the same abstraction
+// gofid.go and idgen.go each expose as WithRandSource/Configure, so a Spec's random suffix can be
+// made deterministic for tests the same way theirs can.
+type entropyPool struct {
+	mu     sync.Mutex
+	source io.Reader
+	buf    []byte
+}
+
+// Option configures the package-level entropy source used by randString.
+type Option func(*entropyPool)
+
+// WithRandSource overrides the entropy source used to generate a Spec's random suffix. It is
+// primarily intended for tests that need deterministic, reproducible output.
+func WithRandSource(source io.Reader) Option {
+	return func(p *entropyPool) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.source = source
+		p.buf = nil
+	}
+}
+
+// newEntropyPool returns an entropyPool reading from crypto/rand by default.
+func newEntropyPool() *entropyPool {
+	return &entropyPool{source: cryptorand.Reader}
+}
+
+// Configure applies opts to the package-level entropy source used by randString.
+func Configure(opts ...Option) {
+	for _, opt := range opts {
+		opt(defaultPool)
+	}
+}
+
+// next returns n random characters drawn from letterBytes, reading fresh entropy in blocks and
+// discarding biased bytes via rejection sampling. It is safe to call concurrently.
+func (p *entropyPool) next(n int) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]byte, n)
+	for i := 0; i < n; {
+		if len(p.buf) == 0 {
+			block := make([]byte, 64)
+			if _, err := io.ReadFull(p.source, block); err != nil {
+				panic("fid: failed to read entropy: " + err.Error())
+			}
+			p.buf = block
+		}
+
+		b := p.buf[0]
+		p.buf = p.buf[1:]
+		if b >= maxLetterByte {
+			continue
+		}
+
+		out[i] = letterBytes[b%byte(len(letterBytes))]
+		i++
+	}
+
+	return string(out)
+}
+
+// defaultPool is the package-level entropy source used by randString.
+var defaultPool = newEntropyPool()
+
+// randString returns n cryptographically random characters drawn from letterBytes, safe for
+// concurrent use.
+func randString(n int) string {
+	return defaultPool.next(n)
+}
+
+// Description is the decoded form of an ID, as returned by Spec.Describe and the package-level
+// Describe.
+type Description struct {
+	Spec         string
+	Indicator    string
+	VendorKey    string
+	App          string
+	Type         string
+	Location     string
+	TimeKey      string
+	Time         time.Time
+	RandomString string
+
+	// VendorName, AppName, TypeName and LocationName are populated by Registry.Describe from a
+	// Registry's code-to-name mappings. They are left empty when describing without a registry,
+	// or when a registry doesn't recognise the corresponding code.
+	VendorName   string
+	AppName      string
+	TypeName     string
+	LocationName string
+}
+
+// Layout describes the dash-delimited segment order and the regex a spec's IDs must match.
+type Layout struct {
+	// Name identifies the spec for diagnostics and Description.Spec, e.g. "gofid.v1".
+	Name string
+
+	// Elements lists the dash-delimited segments in order.
+	Elements []string
+
+	// Regex matches a full ID in this layout.
+	Regex *regexp.Regexp
+}
+
+// TimeKeyEncoding converts between a time.Time and a spec's textual time key.
+type TimeKeyEncoding interface {
+	Encode(t time.Time) (string, error)
+	Decode(key string) (time.Time, error)
+}
+
+// ChecksumScheme optionally tags and verifies an ID with a vendor-secret derived checksum.
+type ChecksumScheme interface {
+	// Apply returns canonical with its checksum tail applied, or canonical unchanged when
+	// vendorSecret is empty.
+	Apply(canonical, vendorSecret string) string
+
+	// Verify reports whether id's checksum tail matches vendorSecret. Always true when
+	// vendorSecret is empty.
+	Verify(id, vendorSecret string) (bool, error)
+}
+
+// Spec describes one versioned ID format: its layout, time key encoding and checksum scheme, plus
+// the Generate/Describe operations built from them.
+type Spec interface {
+	Layout() Layout
+	TimeKeyEncoding() TimeKeyEncoding
+	ChecksumScheme() ChecksumScheme
+
+	// Generate returns a new ID in this spec's format. indicator defaults to "E" when empty.
+	Generate(indicator, vendor, app, nType, priLocation, vendorSecret string) (string, error)
+
+	// Describe decodes id according to this spec, returning an error if it does not match.
+	Describe(id string) (Description, error)
+}
+
+// Specs lists the built-in specs, in the order Describe tries them.
+var Specs = []Spec{SpecV1Gofid, SpecV1Idgen}
+
+var (
+	// SpecV1Gofid is gofid's original layout: E{vendor}{app}{type}-{time}-{location}-{random},
+	// with a descending (reversed) time key. A vendor secret tags new IDs with a versioned
+	// HMAC-SHA256 tail; IDs carrying the legacy single-character MD5 tail still verify.
+	SpecV1Gofid Spec = specV1Gofid{}
+
+	// SpecV1Idgen is idgen's original layout: {time}-E{vendor}{type}{subType}-{location}-{random},
+	// with an ascending, '='-padded time key and no checksum support.
+	SpecV1Idgen Spec = specV1Idgen{}
+)
+
+// Describe auto-detects which of Specs id belongs to by regex and decodes it.
+func Describe(id string) (Description, error) {
+	for _, spec := range Specs {
+		if spec.Layout().Regex.MatchString(id) {
+			return spec.Describe(id)
+		}
+	}
+
+	return Description{}, errors.New("fid: id does not match any known spec")
+}
+
+// descendingBase36TimeKey is gofid's time key: a millisecond timestamp reversed against
+// maxTimestampBase so natural byte order sorts newest-first.
+type descendingBase36TimeKey struct{}
+
+func (descendingBase36TimeKey) Encode(t time.Time) (string, error) {
+	miliTime := t.UnixNano() / 1000000
+	revMiliTime := maxTimestampBase - miliTime
+	key := strings.ToUpper(strconv.FormatInt(revMiliTime, timeKeyBase))
+
+	if pad := timeKeyLength - len(key); pad > 0 {
+		key = strings.Repeat("0", pad) + key
+	} else if pad < 0 {
+		return "", errors.New("fid: invalid time key")
+	}
+
+	return key, nil
+}
+
+func (descendingBase36TimeKey) Decode(key string) (time.Time, error) {
+	msInt, err := strconv.ParseInt(key, timeKeyBase, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	msSinceEpoch := maxTimestampBase - msInt
+	return time.Unix(0, msSinceEpoch*int64(time.Millisecond)), nil
+}
+
+// ascendingBase36TimeKey is idgen's time key: the raw millisecond timestamp, right-padded with '='
+// so natural byte order sorts oldest-first.
+type ascendingBase36TimeKey struct{}
+
+func (ascendingBase36TimeKey) Encode(t time.Time) (string, error) {
+	miliTime := t.UnixNano() / 1000000
+	key := strings.ToUpper(strconv.FormatInt(miliTime, timeKeyBase))
+
+	if pad := timeKeyLength - len(key); pad > 0 {
+		key = key + strings.Repeat("=", pad)
+	} else if pad < 0 {
+		return "", errors.New("fid: invalid time key")
+	}
+
+	return key, nil
+}
+
+func (ascendingBase36TimeKey) Decode(key string) (time.Time, error) {
+	msInt, err := strconv.ParseInt(strings.ReplaceAll(key, "=", ""), timeKeyBase, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, msInt*int64(time.Millisecond)), nil
+}
+
+// md5TailChecksum is gofid's legacy checksum: the random suffix's last character is replaced with
+// one uppercase hex nibble of md5(vendorSecret + canonicalID[:-1]).
+type md5TailChecksum struct{}
+
+func (md5TailChecksum) Apply(canonical, vendorSecret string) string {
+	if vendorSecret == "" {
+		return canonical
+	}
+
+	stripped := canonical[:len(canonical)-1]
+	h := md5.New()
+	h.Write([]byte(vendorSecret + stripped))
+	return stripped + strings.ToUpper(hex.EncodeToString(h.Sum(nil))[0:1])
+}
+
+func (md5TailChecksum) Verify(id, vendorSecret string) (bool, error) {
+	if vendorSecret == "" {
+		return true, nil
+	}
+
+	checkChar := strings.ToUpper(id[len(id)-1:])
+	idMinusCS := id[:len(id)-1]
+	h := md5.New()
+	h.Write([]byte(vendorSecret + idMinusCS))
+
+	if strings.ToUpper(hex.EncodeToString(h.Sum(nil))[0:1]) != checkChar {
+		return false, errors.New("fid: checksum does not match vendor secret")
+	}
+
+	return true, nil
+}
+
+// canonicalGofidID strips any checksum tail from a gofid.v1 id, returning the shared
+// indicator+vendor+app+type/time/location/random components common to every checksum scheme. For
+// an unsigned or legacy-MD5-tagged id (idLength characters) this is id itself; for an HMAC-tagged
+// id it is the idLength-1 character prefix before the checksum scheme version and HMAC tail.
+func canonicalGofidID(id string) string {
+	if len(id) == idLength {
+		return id
+	}
+
+	return id[:idLength-1]
+}
+
+// hmacChecksum is SpecV1Gofid's checksum scheme: Apply tags new IDs with a versioned,
+// HMAC-SHA256-based tail (base32-encoded, defaultHMACTailLength characters) instead of the
+// legacy single-character MD5 tail, which gave only 4 bits of forgery resistance from a broken
+// primitive. Verify still accepts the legacy MD5 tail (gated by AllowLegacyMD5) so already-issued
+// gofid IDs keep validating until consumers migrate.
+type hmacChecksum struct {
+	// AllowLegacyMD5 permits an id carrying the legacy MD5 tail to verify. Set false once all
+	// consumers have migrated to the HMAC tail.
+	AllowLegacyMD5 bool
+}
+
+func (c hmacChecksum) Apply(canonical, vendorSecret string) string {
+	if vendorSecret == "" {
+		return canonical
+	}
+
+	stripped := canonical[:len(canonical)-1]
+	return stripped + checksumVersionHMACSHA256 + hmacTail(vendorSecret, stripped, defaultHMACTailLength)
+}
+
+func (c hmacChecksum) Verify(id, vendorSecret string) (bool, error) {
+	if vendorSecret == "" {
+		return true, nil
+	}
+
+	if len(id) == idLength {
+		if !c.AllowLegacyMD5 {
+			return false, errors.New("fid: legacy MD5 checksum scheme is disabled")
+		}
+
+		return md5TailChecksum{}.Verify(id, vendorSecret)
+	}
+
+	canonicalLength := idLength - 1
+	if len(id) <= canonicalLength+1 {
+		return false, errors.New("fid: id is of invalid length")
+	}
+
+	canonical := id[:canonicalLength]
+	version := id[canonicalLength : canonicalLength+1]
+	tail := id[canonicalLength+1:]
+
+	if version != checksumVersionHMACSHA256 {
+		return false, fmt.Errorf("fid: unrecognised checksum scheme version '%s'", version)
+	}
+
+	if hmacTail(vendorSecret, canonical, len(tail)) != tail {
+		return false, errors.New("fid: checksum does not match vendor secret")
+	}
+
+	return true, nil
+}
+
+// hmacTail computes HMAC-SHA256(vendorSecret, canonicalID) and encodes the first n bytes of the
+// digest as uppercase base-32 characters. n must not exceed sha256.Size.
+func hmacTail(vendorSecret, canonicalID string, n int) string {
+	mac := hmac.New(sha256.New, []byte(vendorSecret))
+	mac.Write([]byte(canonicalID))
+	digest := mac.Sum(nil)
+
+	if n > len(digest) {
+		n = len(digest)
+	}
+
+	tail := make([]byte, n)
+	for i := 0; i < n; i++ {
+		tail[i] = base32Alphabet[digest[i]%byte(len(base32Alphabet))]
+	}
+
+	return string(tail)
+}
+
+// noChecksum is used by specs that don't support a vendor-secret checksum tail.
+type noChecksum struct{}
+
+func (noChecksum) Apply(canonical, _ string) string { return canonical }
+
+func (noChecksum) Verify(_, vendorSecret string) (bool, error) {
+	if vendorSecret != "" {
+		return false, errors.New("fid: spec does not support a vendor-secret checksum")
+	}
+
+	return true, nil
+}
+
+// specV1Gofid implements SpecV1Gofid.
+type specV1Gofid struct{}
+
+// gofidRegex matches both an unsigned or legacy-MD5-tagged id (7 random/checksum characters) and
+// an HMAC-tagged id (the remaining 6 random characters, a version character, then the HMAC tail).
+var gofidRegex = regexp.MustCompile(`\A[A-Z][A-Z0-9]{7}-[A-Z0-9=]{9}-[A-Z0-9=]{5}-(?:[A-Z0-9=]{7}|[A-Z0-9=]{6}1[A-Z2-7]{6})\z`)
+
+func (specV1Gofid) Layout() Layout {
+	return Layout{
+		Name:     "gofid.v1",
+		Elements: []string{"indicator+vendor+app+type", "time", "location", "random"},
+		Regex:    gofidRegex,
+	}
+}
+
+func (specV1Gofid) TimeKeyEncoding() TimeKeyEncoding { return descendingBase36TimeKey{} }
+func (specV1Gofid) ChecksumScheme() ChecksumScheme   { return hmacChecksum{AllowLegacyMD5: true} }
+
+func (s specV1Gofid) Generate(indicator, vendor, app, nType, priLocation, vendorSecret string) (string, error) {
+	if len(indicator) != 1 {
+		indicator = "E"
+	}
+
+	if len(vendor) != vendorLength {
+		return "", fmt.Errorf("fid: vendor must be of length '%d'", vendorLength)
+	}
+
+	if len(app) != appTypeLength {
+		return "", fmt.Errorf("fid: app must be of length '%d'", appTypeLength)
+	}
+
+	if len(nType) != appTypeLength {
+		return "", fmt.Errorf("fid: type must be of length '%d'", appTypeLength)
+	}
+
+	if len(priLocation) != locationLength {
+		priLocation = unknownLocation
+	}
+
+	timeKey, err := s.TimeKeyEncoding().Encode(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	canonical := strings.ToUpper(fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s", indicator, vendor, app, nType, delimitChar, timeKey, delimitChar, priLocation, delimitChar, randString(randLength)))
+	return s.ChecksumScheme().Apply(canonical, vendorSecret), nil
+}
+
+func (s specV1Gofid) Describe(id string) (Description, error) {
+	if !s.Layout().Regex.MatchString(id) {
+		return Description{}, fmt.Errorf("fid: id does not match %s layout", s.Layout().Name)
+	}
+
+	if ok, err := s.ChecksumScheme().Verify(id, ""); !ok {
+		return Description{}, err
+	}
+
+	components := strings.Split(canonicalGofidID(id), delimitChar)
+	if len(components) != idElements {
+		return Description{}, errors.New("fid: unexpected element count in id")
+	}
+
+	t, err := s.TimeKeyEncoding().Decode(components[1])
+	if err != nil {
+		return Description{}, err
+	}
+
+	head := components[0]
+	return Description{
+		Spec:         s.Layout().Name,
+		Indicator:    head[0:1],
+		VendorKey:    head[1:4],
+		App:          head[4:6],
+		Type:         head[6:8],
+		Location:     components[2],
+		TimeKey:      components[1],
+		Time:         t,
+		RandomString: components[3],
+	}, nil
+}
+
+// specV1Idgen implements SpecV1Idgen.
+type specV1Idgen struct{}
+
+var idgenRegex = regexp.MustCompile(`\A[A-Z0-9=]{9}-[A-Z][A-Z0-9]{7}-[A-Z0-9=]{5}-[A-Z0-9=]{7}\z`)
+
+func (specV1Idgen) Layout() Layout {
+	return Layout{
+		Name:     "idgen.v1",
+		Elements: []string{"time", "indicator+vendor+type+subType", "location", "random"},
+		Regex:    idgenRegex,
+	}
+}
+
+func (specV1Idgen) TimeKeyEncoding() TimeKeyEncoding { return ascendingBase36TimeKey{} }
+func (specV1Idgen) ChecksumScheme() ChecksumScheme   { return noChecksum{} }
+
+// Generate maps app onto idgen's subType element, since idgen has no separate "app" concept: when
+// app is not a valid subtype code it falls back to nType, matching idgen.New's original behaviour.
+func (s specV1Idgen) Generate(indicator, vendor, app, nType, priLocation, vendorSecret string) (string, error) {
+	if len(indicator) != 1 {
+		indicator = "E"
+	}
+
+	if len(vendor) != vendorLength {
+		return "", fmt.Errorf("fid: vendor must be of length '%d'", vendorLength)
+	}
+
+	if len(nType) != appTypeLength {
+		return "", fmt.Errorf("fid: type must be of length '%d'", appTypeLength)
+	}
+
+	subType := app
+	if len(subType) != appTypeLength {
+		subType = nType
+	}
+
+	if len(priLocation) != locationLength {
+		priLocation = unknownLocation
+	}
+
+	timeKey, err := s.TimeKeyEncoding().Encode(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	canonical := strings.ToUpper(fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s", timeKey, delimitChar, indicator, vendor, nType, subType, delimitChar, priLocation, delimitChar, randString(randLength)))
+	return s.ChecksumScheme().Apply(canonical, vendorSecret), nil
+}
+
+func (s specV1Idgen) Describe(id string) (Description, error) {
+	if !s.Layout().Regex.MatchString(id) {
+		return Description{}, fmt.Errorf("fid: id does not match %s layout", s.Layout().Name)
+	}
+
+	components := strings.Split(id, delimitChar)
+	if len(components) != idElements {
+		return Description{}, errors.New("fid: unexpected element count in id")
+	}
+
+	t, err := s.TimeKeyEncoding().Decode(components[0])
+	if err != nil {
+		return Description{}, err
+	}
+
+	head := components[1]
+	return Description{
+		Spec:         s.Layout().Name,
+		Indicator:    head[0:1],
+		VendorKey:    head[1:4],
+		Type:         head[4:6],
+		App:          head[6:8],
+		Location:     components[2],
+		TimeKey:      components[0],
+		Time:         t,
+		RandomString: components[3],
+	}, nil
+}
@@ -0,0 +1,178 @@
+package fid
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"strings"
+	"testing"
+)
+
+const (
+	testVendor       = "FID"
+	testApp          = "TE"
+	testType         = "ES"
+	testVendorSecret = "bad_secret"
+)
+
+// TestGofidGenerateDescribeRoundTrip tests that SpecV1Gofid generates IDs it can describe again
+func TestGofidGenerateDescribeRoundTrip(t *testing.T) {
+	t.Log("Testing SpecV1Gofid generate/describe round trip")
+	id, err := SpecV1Gofid.Generate("E", testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	d, err := SpecV1Gofid.Describe(id)
+	if err != nil {
+		t.Errorf("Error describing ID: %s", err.Error())
+	}
+
+	if d.VendorKey != testVendor || d.App != testApp || d.Type != testType {
+		t.Errorf("Described fields did not match input: %+v", d)
+	}
+}
+
+// TestIdgenGenerateDescribeRoundTrip tests that SpecV1Idgen generates IDs it can describe again
+func TestIdgenGenerateDescribeRoundTrip(t *testing.T) {
+	t.Log("Testing SpecV1Idgen generate/describe round trip")
+	id, err := SpecV1Idgen.Generate("E", testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	d, err := SpecV1Idgen.Describe(id)
+	if err != nil {
+		t.Errorf("Error describing ID: %s", err.Error())
+	}
+
+	if d.VendorKey != testVendor || d.Type != testType {
+		t.Errorf("Described fields did not match input: %+v", d)
+	}
+}
+
+// TestDescribeAutoDetectsSpec tests that the package-level Describe picks the right spec for each
+// layout
+func TestDescribeAutoDetectsSpec(t *testing.T) {
+	t.Log("Testing spec auto-detection")
+	gofidID, err := SpecV1Gofid.Generate("E", testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating gofid ID: %s", err.Error())
+	}
+
+	idgenID, err := SpecV1Idgen.Generate("E", testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating idgen ID: %s", err.Error())
+	}
+
+	d, err := Describe(gofidID)
+	if err != nil || d.Spec != "gofid.v1" {
+		t.Errorf("Expected gofid.v1, got %+v, err %v", d, err)
+	}
+
+	d, err = Describe(idgenID)
+	if err != nil || d.Spec != "idgen.v1" {
+		t.Errorf("Expected idgen.v1, got %+v, err %v", d, err)
+	}
+}
+
+// TestGofidChecksumVerification tests that SpecV1Gofid's checksum scheme rejects a bad secret
+func TestGofidChecksumVerification(t *testing.T) {
+	t.Log("Testing SpecV1Gofid checksum verification")
+	id, err := SpecV1Gofid.Generate("E", testVendor, testApp, testType, "", testVendorSecret)
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	if ok, err := SpecV1Gofid.ChecksumScheme().Verify(id, testVendorSecret); !ok {
+		t.Errorf("Checksum failed to verify with correct secret: %s", err.Error())
+	}
+
+	if ok, _ := SpecV1Gofid.ChecksumScheme().Verify(id, testVendorSecret+"wrong"); ok {
+		t.Errorf("Checksum verified with incorrect secret")
+	}
+}
+
+// TestGofidChecksumSchemeRejectsLegacyMD5 tests that hmacChecksum's AllowLegacyMD5 flag can
+// disable acceptance of the legacy single-character MD5 tail
+func TestGofidChecksumSchemeRejectsLegacyMD5(t *testing.T) {
+	t.Log("Testing hmacChecksum.AllowLegacyMD5")
+	canonical, err := (specV1Gofid{}).Generate("E", testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating canonical ID: %s", err.Error())
+	}
+
+	id := md5TailChecksum{}.Apply(canonical, testVendorSecret)
+
+	strict := hmacChecksum{AllowLegacyMD5: false}
+	if ok, _ := strict.Verify(id, testVendorSecret); ok {
+		t.Errorf("Legacy MD5-tagged ID verified with AllowLegacyMD5 disabled")
+	}
+
+	lenient := hmacChecksum{AllowLegacyMD5: true}
+	if ok, err := lenient.Verify(id, testVendorSecret); !ok {
+		t.Errorf("Legacy MD5-tagged ID failed verification with AllowLegacyMD5 enabled: %s", err.Error())
+	}
+}
+
+// TestHMACTailVerification tests that an HMAC-tagged canonical ID verifies with the right vendor
+// secret, fails with the wrong one, and still matches SpecV1Gofid's layout regex
+func TestHMACTailVerification(t *testing.T) {
+	t.Log("Testing HMAC checksum tail verification")
+	canonical, err := (specV1Gofid{}).Generate("E", testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating canonical ID: %s", err.Error())
+	}
+
+	scheme := hmacChecksum{AllowLegacyMD5: true}
+	id := scheme.Apply(canonical, testVendorSecret)
+
+	if !SpecV1Gofid.Layout().Regex.MatchString(id) {
+		t.Errorf("HMAC-tagged ID did not match SpecV1Gofid's layout: %s", id)
+	}
+
+	if ok, err := scheme.Verify(id, testVendorSecret); !ok {
+		t.Errorf("HMAC-tagged ID failed verification with correct secret: %s", err.Error())
+	}
+
+	if ok, _ := scheme.Verify(id, testVendorSecret+"wrong"); ok {
+		t.Errorf("HMAC-tagged ID verified with incorrect secret")
+	}
+}
+
+// TestGofidDescribeSignedID tests that SpecV1Gofid.Describe correctly strips the checksum scheme
+// version and HMAC tail from an HMAC-tagged ID before decoding its components, rather than
+// absorbing them into RandomString
+func TestGofidDescribeSignedID(t *testing.T) {
+	t.Log("Testing SpecV1Gofid.Describe of an HMAC-tagged ID")
+	id, err := SpecV1Gofid.Generate("E", testVendor, testApp, testType, "", testVendorSecret)
+	if err != nil {
+		t.Errorf("Error generating signed ID: %s", err.Error())
+	}
+
+	d, err := SpecV1Gofid.Describe(id)
+	if err != nil {
+		t.Errorf("Error describing signed ID: %s", err.Error())
+	}
+
+	if len(d.RandomString) != randLength-1 {
+		t.Errorf("RandomString was of unexpected length: %d (%q)", len(d.RandomString), d.RandomString)
+	}
+
+	if d.VendorKey != testVendor || d.App != testApp || d.Type != testType {
+		t.Errorf("Described fields did not match input: %+v", d)
+	}
+}
+
+// TestConfigureWithRandSource tests that Configure/WithRandSource lets randString be driven by a
+// deterministic entropy source, the same way gofid.Configure/idgen.Configure do
+func TestConfigureWithRandSource(t *testing.T) {
+	t.Log("Testing fid.Configure with a deterministic entropy source")
+	defer Configure(WithRandSource(cryptorand.Reader))
+
+	Configure(WithRandSource(bytes.NewReader(bytes.Repeat([]byte{0}, 64))))
+
+	want := strings.Repeat(string(letterBytes[0]), randLength)
+	if got := randString(randLength); got != want {
+		t.Errorf("randString with deterministic zero source = %q, want %q", got, want)
+	}
+}
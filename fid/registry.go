@@ -0,0 +1,170 @@
+package fid
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AppInfo describes a known app code and the type codes registered under it.
+type AppInfo struct {
+	Name  string            `json:"name"`
+	Types map[string]string `json:"types,omitempty"`
+}
+
+// VendorInfo describes a known vendor code and the app codes registered under it.
+type VendorInfo struct {
+	Name string             `json:"name"`
+	Apps map[string]AppInfo `json:"apps,omitempty"`
+}
+
+// Registry maps vendor, app, type and location codes to human-readable names, turning opaque IDs
+// like "EFORTKPS-...-USC1B-..." into self-describing objects for logs and admin UIs. The zero value
+// is an empty, non-strict Registry.
+type Registry struct {
+	// Strict makes Generate reject vendor/app/type codes the registry doesn't recognise. Location
+	// codes are never checked against the registry; only a location of the wrong length falls back
+	// to unknownLocation, in the spec's own Generate.
+	Strict bool `json:"strict"`
+
+	Vendors   map[string]VendorInfo `json:"vendors,omitempty"`
+	Locations map[string]string     `json:"locations,omitempty"`
+}
+
+// NewRegistry returns an empty, non-strict Registry ready to be built up with AddVendor, AddApp,
+// AddType and AddLocation.
+func NewRegistry() *Registry {
+	return &Registry{
+		Vendors:   map[string]VendorInfo{},
+		Locations: map[string]string{},
+	}
+}
+
+// LoadRegistryJSON decodes a Registry from JSON. The format matches Registry's own JSON tags, so a
+// YAML document with the same shape can be loaded by decoding it to a Registry with any
+// JSON-tag-aware YAML library and passing the result through directly.
+func LoadRegistryJSON(data []byte) (*Registry, error) {
+	r := NewRegistry()
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("fid: invalid registry: %s", err.Error())
+	}
+
+	return r, nil
+}
+
+// AddVendor registers name for vendor code.
+func (r *Registry) AddVendor(code, name string) {
+	if r.Vendors == nil {
+		r.Vendors = map[string]VendorInfo{}
+	}
+
+	r.Vendors[code] = VendorInfo{Name: name, Apps: r.Vendors[code].Apps}
+}
+
+// AddApp registers name for appCode within vendorCode, returning an error if vendorCode is not
+// already registered.
+func (r *Registry) AddApp(vendorCode, appCode, name string) error {
+	vendor, ok := r.Vendors[vendorCode]
+	if !ok {
+		return fmt.Errorf("fid: unknown vendor code '%s'", vendorCode)
+	}
+
+	if vendor.Apps == nil {
+		vendor.Apps = map[string]AppInfo{}
+	}
+
+	vendor.Apps[appCode] = AppInfo{Name: name, Types: vendor.Apps[appCode].Types}
+	r.Vendors[vendorCode] = vendor
+	return nil
+}
+
+// AddType registers name for typeCode within vendorCode/appCode, returning an error if the vendor
+// or app is not already registered.
+func (r *Registry) AddType(vendorCode, appCode, typeCode, name string) error {
+	vendor, ok := r.Vendors[vendorCode]
+	if !ok {
+		return fmt.Errorf("fid: unknown vendor code '%s'", vendorCode)
+	}
+
+	app, ok := vendor.Apps[appCode]
+	if !ok {
+		return fmt.Errorf("fid: unknown app code '%s' for vendor '%s'", appCode, vendorCode)
+	}
+
+	if app.Types == nil {
+		app.Types = map[string]string{}
+	}
+
+	app.Types[typeCode] = name
+	vendor.Apps[appCode] = app
+	r.Vendors[vendorCode] = vendor
+	return nil
+}
+
+// AddLocation registers name for location code.
+func (r *Registry) AddLocation(code, name string) {
+	if r.Locations == nil {
+		r.Locations = map[string]string{}
+	}
+
+	r.Locations[code] = name
+}
+
+// validate checks vendor/app/type codes against the registry, returning an error naming the first
+// unrecognised code.
+func (r *Registry) validate(vendor, app, nType string) error {
+	vendorInfo, ok := r.Vendors[vendor]
+	if !ok {
+		return fmt.Errorf("fid: unknown vendor code '%s'", vendor)
+	}
+
+	appInfo, ok := vendorInfo.Apps[app]
+	if !ok {
+		return fmt.Errorf("fid: unknown app code '%s' for vendor '%s'", app, vendor)
+	}
+
+	if _, ok := appInfo.Types[nType]; !ok {
+		return fmt.Errorf("fid: unknown type code '%s' for vendor '%s' app '%s'", nType, vendor, app)
+	}
+
+	return nil
+}
+
+// Generate generates an ID via spec, rejecting unrecognised vendor/app/type codes first when
+// r.Strict is set.
+func (r *Registry) Generate(spec Spec, indicator, vendor, app, nType, priLocation, vendorSecret string) (string, error) {
+	if r.Strict {
+		if err := r.validate(vendor, app, nType); err != nil {
+			return "", err
+		}
+	}
+
+	return spec.Generate(indicator, vendor, app, nType, priLocation, vendorSecret)
+}
+
+// Describe decodes id with the package-level Describe, then populates VendorName, AppName,
+// TypeName and LocationName from the registry's mappings, leaving them empty for codes the
+// registry doesn't recognise.
+func (r *Registry) Describe(id string) (Description, error) {
+	d, err := Describe(id)
+	if err != nil {
+		return Description{}, err
+	}
+
+	r.annotate(&d)
+	return d, nil
+}
+
+// annotate populates d's *Name fields from the registry's mappings.
+func (r *Registry) annotate(d *Description) {
+	vendor, ok := r.Vendors[d.VendorKey]
+	if ok {
+		d.VendorName = vendor.Name
+
+		if app, ok := vendor.Apps[d.App]; ok {
+			d.AppName = app.Name
+			d.TypeName = app.Types[d.Type]
+		}
+	}
+
+	d.LocationName = r.Locations[d.Location]
+}
@@ -0,0 +1,106 @@
+package fid
+
+import "testing"
+
+// buildTestRegistry returns a Registry with one vendor/app/type/location registered for tests.
+func buildTestRegistry(t *testing.T) *Registry {
+	r := NewRegistry()
+	r.AddVendor(testVendor, "Fortifi")
+
+	if err := r.AddApp(testVendor, testApp, "Test App"); err != nil {
+		t.Fatalf("Error adding app: %s", err.Error())
+	}
+
+	if err := r.AddType(testVendor, testApp, testType, "Entity Subtype"); err != nil {
+		t.Fatalf("Error adding type: %s", err.Error())
+	}
+
+	r.AddLocation("MISCR", "Unknown Region")
+	return r
+}
+
+// TestRegistryDescribePopulatesNames tests that Registry.Describe fills in the *Name fields for
+// codes the registry recognises
+func TestRegistryDescribePopulatesNames(t *testing.T) {
+	t.Log("Testing registry-driven describe")
+	r := buildTestRegistry(t)
+
+	id, err := SpecV1Gofid.Generate("E", testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	d, err := r.Describe(id)
+	if err != nil {
+		t.Errorf("Error describing ID: %s", err.Error())
+	}
+
+	if d.VendorName != "Fortifi" || d.AppName != "Test App" || d.TypeName != "Entity Subtype" || d.LocationName != "Unknown Region" {
+		t.Errorf("Registry did not populate names correctly: %+v", d)
+	}
+}
+
+// TestRegistryStrictGenerateRejectsUnknownCodes tests that Strict rejects codes the registry
+// doesn't recognise, and allows registered ones
+func TestRegistryStrictGenerateRejectsUnknownCodes(t *testing.T) {
+	t.Log("Testing strict registry-driven generate")
+	r := buildTestRegistry(t)
+	r.Strict = true
+
+	if _, err := r.Generate(SpecV1Gofid, "E", testVendor, testApp, testType, "", ""); err != nil {
+		t.Errorf("Strict generate rejected a known vendor/app/type: %s", err.Error())
+	}
+
+	if _, err := r.Generate(SpecV1Gofid, "E", "ZZZ", testApp, testType, "", ""); err == nil {
+		t.Errorf("Strict generate accepted an unknown vendor code")
+	}
+
+	if _, err := r.Generate(SpecV1Gofid, "E", testVendor, "ZZ", testType, "", ""); err == nil {
+		t.Errorf("Strict generate accepted an unknown app code")
+	}
+}
+
+// TestRegistryZeroValueAddVendor tests that a zero-value Registry doesn't panic when built up
+// directly, without going through NewRegistry
+func TestRegistryZeroValueAddVendor(t *testing.T) {
+	t.Log("Testing zero-value registry")
+	var r Registry
+	r.AddVendor(testVendor, "Fortifi")
+
+	if err := r.AddApp(testVendor, testApp, "Test App"); err != nil {
+		t.Errorf("Error adding app to zero-value registry: %s", err.Error())
+	}
+}
+
+// TestLoadRegistryJSON tests that LoadRegistryJSON decodes a Registry from JSON
+func TestLoadRegistryJSON(t *testing.T) {
+	t.Log("Testing registry JSON loading")
+	data := []byte(`{
+		"strict": true,
+		"vendors": {
+			"FID": {
+				"name": "Fortifi",
+				"apps": {
+					"TE": {
+						"name": "Test App",
+						"types": {"ES": "Entity Subtype"}
+					}
+				}
+			}
+		},
+		"locations": {"MISCR": "Unknown Region"}
+	}`)
+
+	r, err := LoadRegistryJSON(data)
+	if err != nil {
+		t.Errorf("Error loading registry: %s", err.Error())
+	}
+
+	if !r.Strict {
+		t.Errorf("Expected Strict to be true")
+	}
+
+	if err := r.validate(testVendor, testApp, testType); err != nil {
+		t.Errorf("Loaded registry failed to validate known codes: %s", err.Error())
+	}
+}
@@ -5,14 +5,18 @@ package gofid
 **/
 
 import (
+	"crypto/hmac"
 	"crypto/md5"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"math/rand"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -86,8 +90,9 @@ const (
 		IndicatorTimeSeries + IndicatorTimeSeries + IndicatorRelationship + IndicatorNote + IndicatorFile
 )
 
-// Generate returns a new ID in Fortifi Open ID format
-func Generate(systemIndicator TypeIndicator, vendor, app, nType, priLocation, vendorSecret string) (string, error) {
+// buildCanonicalID assembles the indicator/vendor/app/type/time/location/random components of an
+// ID, before any checksum tail is applied
+func buildCanonicalID(systemIndicator TypeIndicator, vendor, app, nType, priLocation string) (string, error) {
 	timeKey, err := getBase36TimeKey(time.Now())
 	if err != nil {
 		return "", err
@@ -98,45 +103,116 @@ func Generate(systemIndicator TypeIndicator, vendor, app, nType, priLocation, ve
 		systemIndicator = IndicatorEntity
 	}
 
-	if len(vendor) != vendorLength {
-		return "", fmt.Errorf("Vendor must be of length '%d'", vendorLength)
+	if err := validateComponents(vendor, app, nType); err != nil {
+		return "", err
 	}
 
-	if len(app) != typeElementLength {
-		return "", fmt.Errorf("App must be of length '%d'", appElementLength)
+	if len(priLocation) != priLocationLength {
+		priLocation = unknownLocationValue
 	}
 
-	if len(nType) != typeElementLength {
-		return "", fmt.Errorf("Type must be of length '%d'", typeElementLength)
+	randomString := getRandString(randLen)
+	return strings.ToUpper(fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s", systemIndicator, vendor, app, nType, delimitChar, timeKey, delimitChar, priLocation, delimitChar, randomString)), nil
+}
+
+// Generate returns a new ID in Fortifi Open ID format, tagged with the legacy single-character MD5
+// checksum when vendorSecret is non-empty. See GenerateSigned for the stronger HMAC-SHA256 scheme.
+func Generate(systemIndicator TypeIndicator, vendor, app, nType, priLocation, vendorSecret string) (string, error) {
+	preResult, err := buildCanonicalID(systemIndicator, vendor, app, nType, priLocation)
+	if err != nil {
+		return "", err
 	}
 
-	if len(priLocation) != priLocationLength {
-		priLocation = unknownLocationValue
+	if len(vendorSecret) == 0 {
+		return preResult, nil
 	}
 
-	randomString := getRandString(randLen)
-	result := ""
-	preResult := strings.ToUpper(fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s", systemIndicator, vendor, app, nType, delimitChar, timeKey, delimitChar, priLocation, delimitChar, randomString))
-
-	if len(vendorSecret) > 0 {
-		preResult = preResult[:len(preResult)-1]
-		h := md5.New()
-		h.Write([]byte(vendorSecret + preResult))
-		hexEncoding := hex.EncodeToString(h.Sum(nil))
-		result = preResult + strings.ToUpper(string(hexEncoding[0]))
-	} else {
-		result = preResult
+	preResult = preResult[:len(preResult)-1]
+	h := md5.New()
+	h.Write([]byte(vendorSecret + preResult))
+	hexEncoding := hex.EncodeToString(h.Sum(nil))
+	return preResult + strings.ToUpper(string(hexEncoding[0])), nil
+}
+
+// GenerateSigned returns a new ID tagged with an HMAC-SHA256 based checksum instead of the legacy
+// MD5 tail. Unlike Generate, the result is longer than idLength: the canonical id (idLength-1
+// characters) is followed by a one-character checksum scheme version and tailLen base-32
+// characters. tailLen defaults to defaultHMACTailLength when <= 0.
+func GenerateSigned(systemIndicator TypeIndicator, vendor, app, nType, priLocation, vendorSecret string, tailLen int) (string, error) {
+	if tailLen <= 0 {
+		tailLen = defaultHMACTailLength
 	}
 
-	return result, nil
+	preResult, err := buildCanonicalID(systemIndicator, vendor, app, nType, priLocation)
+	if err != nil {
+		return "", err
+	}
+
+	canonical := preResult[:len(preResult)-1]
+	return canonical + checksumVersionHMACSHA256 + hmacTail(vendorSecret, canonical, tailLen), nil
 }
 
-// Verify is true if string is a valid Fortifi Open ID
+// defaultHMACTailLength is the number of base-32 characters GenerateSigned uses for its
+// HMAC-SHA256 tail by default (~30 bits of forgery resistance)
+const defaultHMACTailLength = 6
+
+const (
+	checksumVersionHMACSHA256 = "1"
+	base32Alphabet            = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+)
+
+// canonicalRegex matches a canonical ID (idLength-1 characters): the same layout as idRegex, minus
+// the final random-suffix character reserved for a checksum tail
+var canonicalRegex = regexp.MustCompile("[A-Z0-9=]{8}-[A-Z0-9=]{9}-[A-Z0-9=]{5}-[A-Z0-9=]{6}\\z")
+
+// hmacTail computes HMAC-SHA256(vendorSecret, canonicalID) and encodes the first n bytes of the
+// digest as uppercase base-32 characters. n must not exceed sha256.Size.
+func hmacTail(vendorSecret, canonicalID string, n int) string {
+	mac := hmac.New(sha256.New, []byte(vendorSecret))
+	mac.Write([]byte(canonicalID))
+	digest := mac.Sum(nil)
+
+	if n > len(digest) {
+		n = len(digest)
+	}
+
+	tail := make([]byte, n)
+	for i := 0; i < n; i++ {
+		tail[i] = base32Alphabet[digest[i]%byte(len(base32Alphabet))]
+	}
+
+	return string(tail)
+}
+
+// VerifyOptions controls which checksum schemes Verify accepts
+type VerifyOptions struct {
+	// AllowLegacyMD5 permits IDs tagged with the legacy single-character MD5 checksum to pass
+	// verification. Set false once all consumers have migrated to GenerateSigned.
+	AllowLegacyMD5 bool
+}
+
+// Verify is true if string is a valid Fortifi Open ID. Both the legacy MD5-tagged and the newer
+// HMAC-SHA256-tagged (see GenerateSigned) schemes are accepted; use VerifyWithOptions to reject
+// the legacy scheme.
 func Verify(id, vendorSecret string) (bool, error) {
-	if len(id) != idLength {
+	return VerifyWithOptions(id, vendorSecret, VerifyOptions{AllowLegacyMD5: true})
+}
+
+// VerifyWithOptions is Verify with explicit control over which checksum schemes are accepted
+func VerifyWithOptions(id, vendorSecret string, opts VerifyOptions) (bool, error) {
+	if len(id) < idLength {
 		return false, errors.New("ID is of invalid length")
 	}
 
+	if len(id) == idLength {
+		return verifyLegacyMD5(id, vendorSecret, opts)
+	}
+
+	return verifyHMAC(id, vendorSecret)
+}
+
+// verifyLegacyMD5 validates an ID using the original single-character MD5 checksum scheme
+func verifyLegacyMD5(id, vendorSecret string, opts VerifyOptions) (bool, error) {
 	re := regexp.MustCompile(idRegex)
 	match := re.FindStringSubmatch(id)
 	if len(match) != 1 {
@@ -148,19 +224,73 @@ func Verify(id, vendorSecret string) (bool, error) {
 		return false, errors.New("Unexpected element count in ID")
 	}
 
-	if vendorSecret != "" {
-		checkChar := string(id[len(id)-1:])
-		idMinusCS := string(id[0:(len(id) - 1)])
-		h := md5.New()
-		h.Write([]byte(vendorSecret + idMinusCS))
-		hexEncoding := hex.EncodeToString(h.Sum(nil))
+	if vendorSecret == "" {
+		return true, nil
+	}
+
+	if !opts.AllowLegacyMD5 {
+		return false, errors.New("legacy MD5 checksum scheme is disabled")
+	}
+
+	checkChar := string(id[len(id)-1:])
+	idMinusCS := string(id[0:(len(id) - 1)])
+	h := md5.New()
+	h.Write([]byte(vendorSecret + idMinusCS))
+	hexEncoding := hex.EncodeToString(h.Sum(nil))
+
+	if strings.ToUpper(string(hexEncoding[0])) != checkChar {
+		return false, errors.New("Checksum does not match vendor secret")
+	}
+
+	return true, nil
+}
+
+// verifyHMAC validates an ID tagged with a versioned checksum scheme (currently only
+// checksumVersionHMACSHA256, produced by GenerateSigned)
+func verifyHMAC(id, vendorSecret string) (bool, error) {
+	canonicalLen := idLength - 1
+	if len(id) <= canonicalLen+1 {
+		return false, errors.New("ID is of invalid length")
+	}
+
+	canonical := id[:canonicalLen]
+	if !canonicalRegex.MatchString(canonical) {
+		return false, errors.New("ID format is invalid")
+	}
+
+	if strings.Count(canonical, delimitChar) != idElements-1 {
+		return false, errors.New("Unexpected element count in ID")
+	}
+
+	version := string(id[canonicalLen])
+	tail := id[canonicalLen+1:]
+
+	switch version {
+	case checksumVersionHMACSHA256:
+		if vendorSecret == "" {
+			return true, nil
+		}
 
-		if strings.ToUpper(string(hexEncoding[0])) != checkChar {
+		if hmacTail(vendorSecret, canonical, len(tail)) != tail {
 			return false, errors.New("Checksum does not match vendor secret")
 		}
+
+		return true, nil
+	default:
+		return false, fmt.Errorf("Unrecognised checksum scheme version '%s'", version)
 	}
+}
 
-	return true, nil
+// canonicalID strips any checksum tail from id, returning the shared
+// indicator+vendor+app+type/time/location/random components common to every checksum scheme. For
+// an unsigned or legacy-MD5-tagged id (idLength characters) this is id itself; for a GenerateSigned
+// id it is the idLength-1 character prefix before the checksum scheme version and HMAC tail.
+func canonicalID(id string) string {
+	if len(id) == idLength {
+		return id
+	}
+
+	return id[:idLength-1]
 }
 
 // Describe returns decoded description object for the ID
@@ -170,7 +300,7 @@ func Describe(id string) (Description, error) {
 		return Description{}, err
 	}
 
-	components := strings.Split(id, delimitChar)
+	components := strings.Split(canonicalID(id), delimitChar)
 
 	indicatorCom := components[0]
 	sysIndicator := TypeIndicator(indicatorCom[0:1])
@@ -207,7 +337,7 @@ func getTimeFromID(id string) (time.Time, error) {
 		return time.Time{}, err
 	}
 
-	components := strings.Split(id, delimitChar)
+	components := strings.Split(canonicalID(id), delimitChar)
 	miliseconds := components[1]
 	msInt, err := strconv.ParseInt(miliseconds, 36, 64)
 	if err != nil {
@@ -219,6 +349,23 @@ func getTimeFromID(id string) (time.Time, error) {
 	return time.Unix(0, revMs), nil
 }
 
+// validateComponents checks that vendor/app/type codes match the spec's fixed lengths
+func validateComponents(vendor, app, nType string) error {
+	if len(vendor) != vendorLength {
+		return fmt.Errorf("Vendor must be of length '%d'", vendorLength)
+	}
+
+	if len(app) != appElementLength {
+		return fmt.Errorf("App must be of length '%d'", appElementLength)
+	}
+
+	if len(nType) != typeElementLength {
+		return fmt.Errorf("Type must be of length '%d'", typeElementLength)
+	}
+
+	return nil
+}
+
 // isValidIndicator checks that proposed indicator is valid as per spec
 func isValidIndicator(proposed string) bool {
 	proposed = strings.ToUpper(proposed)
@@ -244,12 +391,261 @@ func getBase36TimeKey(time time.Time) (string, error) {
 	return timeKey, nil
 }
 
-// generates a pseudorandom string
-func getRandString(n int) string {
-	rand.Seed(time.Now().UTC().UnixNano())
-	randBytes := make([]byte, n)
-	for i := range randBytes {
-		randBytes[i] = letterBytes[rand.Intn(len(letterBytes))]
+// ascendingBase36TimeKey returns a millisecond timestamp in base 36 that sorts ascending with time,
+// unlike getBase36TimeKey whose value is reversed against maxTimestampValBase10. Generators in
+// TimeKeyAscending mode use this so IDs remain naturally ordered for range scans in KV stores.
+func ascendingBase36TimeKey(t time.Time) (string, error) {
+	miliTime := t.UnixNano() / 1000000
+	timeKey := strings.ToUpper(strconv.FormatInt(miliTime, timeKeyBase))
+	paddingLen := timeKeyLength - len(timeKey)
+
+	if paddingLen > 0 {
+		timeKey = strings.Repeat("0", paddingLen) + timeKey
 	}
-	return string(randBytes)
+
+	if paddingLen < 0 {
+		return "", errors.New("Invalid time key")
+	}
+
+	return timeKey, nil
+}
+
+// ErrMonotonicOverflow is returned by Generator.Generate when the base-36 random suffix counter
+// wraps past "ZZZZZZZ" within the same millisecond.
+var ErrMonotonicOverflow = errors.New("gofid: monotonic random suffix overflowed")
+
+// TimeKeyMode selects how a Generator encodes the millisecond timestamp in generated IDs.
+type TimeKeyMode int
+
+const (
+	// TimeKeyDescending matches the original gofid behaviour: the time key is reversed against
+	// maxTimestampValBase10, so natural byte order sorts newest-first.
+	TimeKeyDescending TimeKeyMode = iota
+
+	// TimeKeyAscending encodes the raw millisecond timestamp, so natural byte order sorts
+	// oldest-first, like idgen's time key (here zero-padded on the left rather than '='-padded).
+	TimeKeyAscending
+)
+
+// GeneratorOption configures a Generator constructed via NewGenerator.
+type GeneratorOption func(*Generator)
+
+// WithTimeKeyMode sets whether a Generator encodes an ascending or descending time key.
+func WithTimeKeyMode(mode TimeKeyMode) GeneratorOption {
+	return func(g *Generator) { g.mode = mode }
+}
+
+// WithEntropy overrides the entropy source a Generator uses for its random suffix. It is primarily
+// intended for tests that need deterministic, reproducible output.
+func WithEntropy(source io.Reader) GeneratorOption {
+	return func(g *Generator) { g.Entropy = source }
+}
+
+// Generator produces Fortifi Open IDs and, unlike the package-level Generate, guarantees that IDs
+// generated in the same millisecond are monotonically increasing: the random suffix is treated as a
+// base-36 counter that increments rather than being redrawn. A Generator is safe for concurrent use.
+type Generator struct {
+	// Entropy is the source used to seed the random suffix for each new millisecond. Defaults to
+	// crypto/rand.Reader.
+	Entropy io.Reader
+
+	mode TimeKeyMode
+
+	mu         sync.Mutex
+	pool       *entropyPool
+	lastMilli  int64
+	lastSuffix string
+}
+
+// NewGenerator returns a Generator ready for concurrent use, configured by opts.
+func NewGenerator(opts ...GeneratorOption) *Generator {
+	g := &Generator{Entropy: cryptorand.Reader}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.pool = &entropyPool{source: g.Entropy}
+	return g
+}
+
+// Generate returns a new ID, guaranteeing monotonically increasing output within the same
+// millisecond by incrementing the previous random suffix as a base-36 counter.
+func (g *Generator) Generate(indicator TypeIndicator, vendor, app, nType, priLocation string) (string, error) {
+	if !isValidIndicator(string(indicator)) || len(indicator) == 0 {
+		indicator = IndicatorEntity
+	}
+
+	if err := validateComponents(vendor, app, nType); err != nil {
+		return "", err
+	}
+
+	if len(priLocation) != priLocationLength {
+		priLocation = unknownLocationValue
+	}
+
+	now := time.Now()
+
+	var timeKey string
+	var err error
+	if g.mode == TimeKeyAscending {
+		timeKey, err = ascendingBase36TimeKey(now)
+	} else {
+		timeKey, err = getBase36TimeKey(now)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	suffix, err := g.nextSuffix(now)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToUpper(fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s", indicator, vendor, app, nType, delimitChar, timeKey, delimitChar, priLocation, delimitChar, suffix)), nil
+}
+
+// Describe decodes id, an ID previously generated by g, correctly interpreting its time key
+// according to g's TimeKeyMode. The package-level Describe always assumes the original descending
+// encoding, so it silently miscomputes Time for IDs from a Generator configured with
+// WithTimeKeyMode(TimeKeyAscending); call this method instead for IDs from such a Generator.
+func (g *Generator) Describe(id string) (Description, error) {
+	d, err := Describe(id)
+	if err != nil {
+		return Description{}, err
+	}
+
+	if g.mode == TimeKeyAscending {
+		t, err := ascendingTimeFromKey(d.TimeKey)
+		if err != nil {
+			return Description{}, err
+		}
+
+		d.Time = t
+	}
+
+	return d, nil
+}
+
+// ascendingTimeFromKey decodes a time key produced by ascendingBase36TimeKey back into a time.Time.
+func ascendingTimeFromKey(key string) (time.Time, error) {
+	msInt, err := strconv.ParseInt(key, timeKeyBase, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, msInt*int64(time.Millisecond)), nil
+}
+
+// nextSuffix returns the random suffix for the current millisecond, drawing fresh entropy the first
+// time a millisecond is seen and incrementing the previous suffix as a base-36 counter thereafter.
+func (g *Generator) nextSuffix(now time.Time) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	milli := now.UnixNano() / 1000000
+	if milli != g.lastMilli {
+		g.lastMilli = milli
+		g.lastSuffix = g.pool.next(randLen)
+		return g.lastSuffix, nil
+	}
+
+	next, err := incrementSuffix(g.lastSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	g.lastSuffix = next
+	return next, nil
+}
+
+// incrementSuffix increments s by one as a base-36 number over the letterBytes alphabet, carrying
+// from the rightmost character. It returns ErrMonotonicOverflow if s is already "ZZZZZZZ".
+func incrementSuffix(s string) (string, error) {
+	b := []byte(s)
+	for i := len(b) - 1; i >= 0; i-- {
+		idx := strings.IndexByte(letterBytes, b[i])
+		if idx < len(letterBytes)-1 {
+			b[i] = letterBytes[idx+1]
+			return string(b), nil
+		}
+		b[i] = letterBytes[0]
+	}
+
+	return "", ErrMonotonicOverflow
+}
+
+// maxLetterByte is the largest multiple of len(letterBytes) that fits in a byte. Bytes read from the
+// entropy source above this value are rejected rather than reduced with modulo, so every symbol in
+// letterBytes remains equally likely.
+var maxLetterByte = byte(256 - (256 % len(letterBytes)))
+
+// entropyPool buffers reads from an entropy source and hands out bias-free random characters from
+// letterBytes. It is safe for concurrent use by multiple goroutines.
+type entropyPool struct {
+	mu     sync.Mutex
+	source io.Reader
+	buf    []byte
+}
+
+// Option configures the package-level entropy source used by Generate. Generators are configured
+// with GeneratorOption instead.
+type Option func(*entropyPool)
+
+// WithRandSource overrides the entropy source used to generate the random suffix of an ID. It is
+// primarily intended for tests that need deterministic, reproducible output.
+func WithRandSource(source io.Reader) Option {
+	return func(p *entropyPool) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.source = source
+		p.buf = nil
+	}
+}
+
+// newEntropyPool returns an entropyPool reading from crypto/rand by default.
+func newEntropyPool() *entropyPool {
+	return &entropyPool{source: cryptorand.Reader}
+}
+
+// Configure applies opts to the package-level entropy source used by Generate.
+func Configure(opts ...Option) {
+	for _, opt := range opts {
+		opt(defaultPool)
+	}
+}
+
+// next returns n random characters drawn from letterBytes, reading fresh entropy in blocks and
+// discarding biased bytes via rejection sampling. It is safe to call concurrently.
+func (p *entropyPool) next(n int) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]byte, n)
+	for i := 0; i < n; {
+		if len(p.buf) == 0 {
+			block := make([]byte, 64)
+			if _, err := io.ReadFull(p.source, block); err != nil {
+				panic("gofid: failed to read entropy: " + err.Error())
+			}
+			p.buf = block
+		}
+
+		b := p.buf[0]
+		p.buf = p.buf[1:]
+		if b >= maxLetterByte {
+			continue
+		}
+
+		out[i] = letterBytes[b%byte(len(letterBytes))]
+		i++
+	}
+
+	return string(out)
+}
+
+// defaultPool is the package-level entropy source used by Generate.
+var defaultPool = newEntropyPool()
+
+// generates a cryptographically strong random string, safe for concurrent use
+func getRandString(n int) string {
+	return defaultPool.next(n)
 }
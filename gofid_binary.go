@@ -0,0 +1,247 @@
+package gofid
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fidBinaryLength is the total size in bytes of FID's binary encoding: 1-byte indicator, 3-byte
+// vendor, 2-byte app, 2-byte type, 6-byte millisecond timestamp, 5-byte location and 5-byte
+// random+checksum tail - ~25% smaller than the 32 character ASCII form.
+const fidBinaryLength = 1 + vendorLength + appElementLength + typeElementLength + 6 + priLocationLength + 5
+
+// FID is a decoded Fortifi Open ID, kept around for its compact binary form rather than the 32
+// character ASCII encoding. Use Parse to build one from a string and String to get it back.
+type FID struct {
+	Indicator    TypeIndicator
+	VendorKey    string
+	App          string
+	Type         string
+	Location     string
+	TimeKey      string
+	RandomString string
+}
+
+// Parse decodes id into an FID, validating it against idRegex in the same way as Describe.
+func Parse(id string) (FID, error) {
+	d, err := Describe(id)
+	if err != nil {
+		return FID{}, err
+	}
+
+	return FID{
+		Indicator:    d.Indicator,
+		VendorKey:    d.VendorKey,
+		App:          d.App,
+		Type:         d.Type,
+		Location:     d.Location,
+		TimeKey:      d.TimeKey,
+		RandomString: d.RandomString,
+	}, nil
+}
+
+// String returns the 32 character Fortifi Open ID this FID represents.
+func (f FID) String() string {
+	return strings.ToUpper(fmt.Sprintf("%s%s%s%s%s%s%s%s%s%s", f.Indicator, f.VendorKey, f.App, f.Type, delimitChar, f.TimeKey, delimitChar, f.Location, delimitChar, f.RandomString))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, packing the ID's fields into fidBinaryLength
+// bytes instead of 32 ASCII characters.
+func (f FID) MarshalBinary() ([]byte, error) {
+	if len(f.Indicator) != 1 {
+		return nil, fmt.Errorf("FID: indicator must be of length '1'")
+	}
+
+	if len(f.VendorKey) != vendorLength {
+		return nil, fmt.Errorf("FID: vendor must be of length '%d'", vendorLength)
+	}
+
+	if len(f.App) != appElementLength {
+		return nil, fmt.Errorf("FID: app must be of length '%d'", appElementLength)
+	}
+
+	if len(f.Type) != typeElementLength {
+		return nil, fmt.Errorf("FID: type must be of length '%d'", typeElementLength)
+	}
+
+	if len(f.Location) != priLocationLength {
+		return nil, fmt.Errorf("FID: location must be of length '%d'", priLocationLength)
+	}
+
+	if len(f.TimeKey) != timeKeyLength {
+		return nil, fmt.Errorf("FID: time key must be of length '%d'", timeKeyLength)
+	}
+
+	if len(f.RandomString) != randLen {
+		return nil, fmt.Errorf("FID: random string must be of length '%d'", randLen)
+	}
+
+	vendor, err := packBase36(f.VendorKey)
+	if err != nil {
+		return nil, fmt.Errorf("FID: invalid vendor '%s': %s", f.VendorKey, err.Error())
+	}
+
+	timeVal, err := packBase36(f.TimeKey)
+	if err != nil {
+		return nil, fmt.Errorf("FID: invalid time key '%s': %s", f.TimeKey, err.Error())
+	}
+
+	tail, err := packBase36(f.RandomString)
+	if err != nil {
+		return nil, fmt.Errorf("FID: invalid random string '%s': %s", f.RandomString, err.Error())
+	}
+
+	buf := make([]byte, 0, fidBinaryLength)
+	buf = append(buf, f.Indicator[0])
+	buf = appendUint(buf, vendor, vendorLength)
+	buf = append(buf, f.App...)
+	buf = append(buf, f.Type...)
+	buf = appendUint(buf, timeVal, 6)
+	buf = append(buf, f.Location...)
+	buf = appendUint(buf, tail, 5)
+
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, reversing MarshalBinary.
+func (f *FID) UnmarshalBinary(data []byte) error {
+	if len(data) != fidBinaryLength {
+		return fmt.Errorf("FID: binary data must be '%d' bytes, got '%d'", fidBinaryLength, len(data))
+	}
+
+	offset := 0
+	indicator := TypeIndicator(data[offset : offset+1])
+	offset++
+
+	vendor := readUint(data[offset : offset+vendorLength])
+	offset += vendorLength
+
+	app := string(data[offset : offset+appElementLength])
+	offset += appElementLength
+
+	nType := string(data[offset : offset+typeElementLength])
+	offset += typeElementLength
+
+	timeVal := readUint(data[offset : offset+6])
+	offset += 6
+
+	location := string(data[offset : offset+priLocationLength])
+	offset += priLocationLength
+
+	tail := readUint(data[offset : offset+5])
+
+	*f = FID{
+		Indicator:    indicator,
+		VendorKey:    unpackBase36(vendor, vendorLength),
+		App:          app,
+		Type:         nType,
+		Location:     location,
+		TimeKey:      unpackBase36(timeVal, timeKeyLength),
+		RandomString: unpackBase36(tail, randLen),
+	}
+
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (f FID) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *FID) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*f = parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the FID as its 32 character string form.
+func (f FID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *FID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*f = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, storing the FID as its compact binary form (e.g. Postgres BYTEA).
+func (f FID) Value() (driver.Value, error) {
+	return f.MarshalBinary()
+}
+
+// Scan implements sql.Scanner, accepting either the binary form written by Value or a 32 character
+// ID string.
+func (f *FID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*f = FID{}
+		return nil
+	case []byte:
+		return f.UnmarshalBinary(v)
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+
+		*f = parsed
+		return nil
+	default:
+		return fmt.Errorf("FID: cannot scan value of type %T", src)
+	}
+}
+
+// packBase36 parses a base-36 string (as used for FID's vendor, time key and random fields) into
+// an integer, reading any '=' padding character as '0'.
+func packBase36(s string) (uint64, error) {
+	return strconv.ParseUint(strings.ReplaceAll(s, "=", "0"), timeKeyBase, 64)
+}
+
+// unpackBase36 is the inverse of packBase36, zero-padding the result to width characters. '='
+// padding read by packBase36 comes back as '0'.
+func unpackBase36(v uint64, width int) string {
+	s := strings.ToUpper(strconv.FormatUint(v, timeKeyBase))
+	if pad := width - len(s); pad > 0 {
+		s = strings.Repeat("0", pad) + s
+	}
+
+	return s
+}
+
+// appendUint appends the n lowest-order bytes of v to buf, big-endian.
+func appendUint(buf []byte, v uint64, n int) []byte {
+	for i := n - 1; i >= 0; i-- {
+		buf = append(buf, byte(v>>(8*uint(i))))
+	}
+
+	return buf
+}
+
+// readUint reads a big-endian unsigned integer from b.
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	return v
+}
@@ -0,0 +1,81 @@
+package gofid
+
+import "testing"
+
+// TestFIDParseStringRoundTrip tests that Parse followed by String reproduces the original ID
+func TestFIDParseStringRoundTrip(t *testing.T) {
+	t.Log("Testing FID Parse/String round trip")
+	id, err := Generate(IndicatorEntity, testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	fid, err := Parse(id)
+	if err != nil {
+		t.Errorf("Error parsing ID: %s", err.Error())
+	}
+
+	if fid.String() != id {
+		t.Errorf("FID did not round trip: got %s, want %s", fid.String(), id)
+	}
+}
+
+// TestFIDBinaryRoundTrip tests that MarshalBinary/UnmarshalBinary reproduce the original FID
+func TestFIDBinaryRoundTrip(t *testing.T) {
+	t.Log("Testing FID binary marshalling")
+	id, err := Generate(IndicatorEntity, testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	fid, err := Parse(id)
+	if err != nil {
+		t.Errorf("Error parsing ID: %s", err.Error())
+	}
+
+	data, err := fid.MarshalBinary()
+	if err != nil {
+		t.Errorf("Error marshalling FID: %s", err.Error())
+	}
+
+	if len(data) != fidBinaryLength {
+		t.Errorf("Binary FID was of unexpected length: %d", len(data))
+	}
+
+	var decoded FID
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Errorf("Error unmarshalling FID: %s", err.Error())
+	}
+
+	if decoded.String() != id {
+		t.Errorf("FID did not survive binary round trip: got %s, want %s", decoded.String(), id)
+	}
+}
+
+// TestFIDJSONRoundTrip tests that MarshalJSON/UnmarshalJSON reproduce the original FID
+func TestFIDJSONRoundTrip(t *testing.T) {
+	t.Log("Testing FID JSON marshalling")
+	id, err := Generate(IndicatorEntity, testVendor, testApp, testType, "", "")
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	fid, err := Parse(id)
+	if err != nil {
+		t.Errorf("Error parsing ID: %s", err.Error())
+	}
+
+	data, err := fid.MarshalJSON()
+	if err != nil {
+		t.Errorf("Error marshalling FID to JSON: %s", err.Error())
+	}
+
+	var decoded FID
+	if err := decoded.UnmarshalJSON(data); err != nil {
+		t.Errorf("Error unmarshalling FID from JSON: %s", err.Error())
+	}
+
+	if decoded.String() != id {
+		t.Errorf("FID did not survive JSON round trip: got %s, want %s", decoded.String(), id)
+	}
+}
@@ -1,8 +1,11 @@
 package gofid
 
 import (
+	"bytes"
+	cryptorand "crypto/rand"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -206,6 +209,191 @@ func TestGetDescription(t *testing.T) {
 	}
 }
 
+// TestGeneratorMonotonicWithinMillisecond tests that IDs generated by a Generator in the same
+// millisecond have a strictly increasing random suffix
+func TestGeneratorMonotonicWithinMillisecond(t *testing.T) {
+	t.Log("Testing generator monotonic suffix")
+	gen := NewGenerator()
+
+	now := time.Now()
+	first, err := gen.nextSuffix(now)
+	if err != nil {
+		t.Errorf("Error generating first suffix: %s", err.Error())
+	}
+
+	second, err := gen.nextSuffix(now)
+	if err != nil {
+		t.Errorf("Error generating second suffix: %s", err.Error())
+	}
+
+	if second <= first {
+		t.Errorf("Suffix did not increase monotonically: %s -> %s", first, second)
+	}
+}
+
+// TestIncrementSuffixOverflow tests that incrementing the maximum suffix returns ErrMonotonicOverflow
+func TestIncrementSuffixOverflow(t *testing.T) {
+	t.Log("Testing monotonic suffix overflow")
+	if _, err := incrementSuffix("ZZZZZZZ"); err != ErrMonotonicOverflow {
+		t.Errorf("Expected ErrMonotonicOverflow, got %v", err)
+	}
+}
+
+// TestGeneratorAscendingTimeKey tests that a Generator configured with TimeKeyAscending produces a
+// time key of the same length as one from ascendingBase36TimeKey
+func TestGeneratorAscendingTimeKey(t *testing.T) {
+	t.Log("Testing generator ascending time key mode")
+	gen := NewGenerator(WithTimeKeyMode(TimeKeyAscending))
+
+	id, err := gen.Generate(IndicatorEntity, testVendor, testApp, testType, "")
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	components := strings.Split(id, delimitChar)
+	ascendingKey, err := ascendingBase36TimeKey(time.Now())
+	if err != nil {
+		t.Errorf("Error generating ascending time key: %s", err.Error())
+	}
+
+	if len(components[1]) != len(ascendingKey) {
+		t.Errorf("Ascending time key was of unexpected length: %s", components[1])
+	}
+}
+
+// TestGeneratorDescribeAscendingTimeKey tests that Generator.Describe correctly decodes the Time of
+// an ID generated in TimeKeyAscending mode, unlike the package-level Describe, which assumes the
+// descending encoding
+func TestGeneratorDescribeAscendingTimeKey(t *testing.T) {
+	t.Log("Testing Generator.Describe with ascending time key mode")
+	gen := NewGenerator(WithTimeKeyMode(TimeKeyAscending))
+
+	before := time.Now()
+	id, err := gen.Generate(IndicatorEntity, testVendor, testApp, testType, "")
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+	after := time.Now()
+
+	d, err := gen.Describe(id)
+	if err != nil {
+		t.Errorf("Error describing ID: %s", err.Error())
+	}
+
+	if d.Time.Before(before.Add(-time.Second)) || d.Time.After(after.Add(time.Second)) {
+		t.Errorf("Generator.Describe decoded an implausible Time: %s (expected between %s and %s)", d.Time, before, after)
+	}
+}
+
+// TestGenerateSignedRoundTrip tests that a GenerateSigned ID verifies with the right vendor secret
+// and fails with the wrong one
+func TestGenerateSignedRoundTrip(t *testing.T) {
+	t.Log("Testing HMAC-signed ID generation and verification")
+	id, err := GenerateSigned(IndicatorEntity, testVendor, testApp, testType, "", testVendorSecret, 0)
+	if err != nil || id == "" {
+		t.Errorf("Error generating signed ID")
+	}
+
+	if len(id) != idLength-1+1+defaultHMACTailLength {
+		t.Errorf("Signed ID was of unexpected length: %d", len(id))
+	}
+
+	if result, err := Verify(id, testVendorSecret); result == false {
+		t.Errorf("Signed ID failed verification: %s", err.Error())
+	}
+
+	if result, _ := Verify(id, testVendorSecret+"qweqwe"); result == true {
+		t.Errorf("Signed ID passed verification with invalid vendor secret")
+	}
+}
+
+// TestDescribeSignedID tests that Describe correctly strips the checksum scheme version and HMAC
+// tail from a GenerateSigned ID before decoding its components, rather than absorbing them into
+// RandomString
+func TestDescribeSignedID(t *testing.T) {
+	t.Log("Testing Describe of a GenerateSigned ID")
+	id, err := GenerateSigned(IndicatorEntity, testVendor, testApp, testType, "", testVendorSecret, 0)
+	if err != nil {
+		t.Errorf("Error generating signed ID: %s", err.Error())
+	}
+
+	d, err := Describe(id)
+	if err != nil {
+		t.Errorf("Error describing signed ID: %s", err.Error())
+	}
+
+	if len(d.RandomString) != randLen-1 {
+		t.Errorf("RandomString was of unexpected length: %d (%q)", len(d.RandomString), d.RandomString)
+	}
+
+	if d.VendorKey != testVendor || d.App != testApp || d.Type != testType {
+		t.Errorf("Described fields did not match input: %+v", d)
+	}
+}
+
+// TestVerifyWithOptionsRejectsLegacyMD5 tests that VerifyOptions.AllowLegacyMD5 can disable the
+// legacy checksum scheme
+func TestVerifyWithOptionsRejectsLegacyMD5(t *testing.T) {
+	t.Log("Testing VerifyOptions.AllowLegacyMD5")
+	id, err := Generate(IndicatorEntity, testVendor, testApp, testType, "", testVendorSecret)
+	if err != nil {
+		t.Errorf("Error generating ID: %s", err.Error())
+	}
+
+	if result, _ := VerifyWithOptions(id, testVendorSecret, VerifyOptions{AllowLegacyMD5: false}); result == true {
+		t.Errorf("Legacy MD5 checksum passed verification with AllowLegacyMD5 disabled")
+	}
+
+	if result, err := VerifyWithOptions(id, testVendorSecret, VerifyOptions{AllowLegacyMD5: true}); result == false {
+		t.Errorf("Legacy MD5 checksum failed verification with AllowLegacyMD5 enabled: %s", err.Error())
+	}
+}
+
+// TestConfigureWithRandSource tests that Configure/WithRandSource lets getRandString be driven by a
+// deterministic entropy source
+func TestConfigureWithRandSource(t *testing.T) {
+	t.Log("Testing Configure with a deterministic entropy source")
+	defer Configure(WithRandSource(cryptorand.Reader))
+
+	Configure(WithRandSource(bytes.NewReader(bytes.Repeat([]byte{0}, 64))))
+
+	want := strings.Repeat(string(letterBytes[0]), randLen)
+	if got := getRandString(randLen); got != want {
+		t.Errorf("getRandString with deterministic zero source = %q, want %q", got, want)
+	}
+}
+
+// TestGenerateConcurrentSafety tests that concurrent calls to Generate and to a single shared
+// Generator's Generate don't race or produce malformed IDs
+func TestGenerateConcurrentSafety(t *testing.T) {
+	t.Log("Testing concurrent Generate and Generator.Generate")
+	const goroutines = 50
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines * 2)
+
+	gen := NewGenerator()
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			id, err := Generate(IndicatorEntity, testVendor, testApp, testType, "", "")
+			if err != nil || len(id) != idLength {
+				t.Errorf("Concurrent Generate produced an invalid ID %q: %v", id, err)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			id, err := gen.Generate(IndicatorEntity, testVendor, testApp, testType, "")
+			if err != nil || len(id) != idLength {
+				t.Errorf("Concurrent Generator.Generate produced an invalid ID %q: %v", id, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
 // Benchmark single fid generation
 func BenchmarkSingleFidGeneration(b *testing.B) {
 	for n := 0; n < 20; n++ {
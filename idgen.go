@@ -5,12 +5,14 @@ package idgen
 **/
 
 import (
+	cryptorand "crypto/rand"
 	"errors"
 	"fmt"
-	"math/rand"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -163,12 +165,78 @@ func getBase32TimeKey(time time.Time) (string, error) {
 	return timeKey, nil
 }
 
-// generates a pseudorandom string
-func getRandString(n int) string {
-	rand.Seed(time.Now().UTC().UnixNano())
-	randBytes := make([]byte, n)
-	for i := range randBytes {
-		randBytes[i] = letterBytes[rand.Intn(len(letterBytes))]
+// maxLetterByte is the largest multiple of len(letterBytes) that fits in a byte. Bytes read from the
+// entropy source above this value are rejected rather than reduced with modulo, so every symbol in
+// letterBytes remains equally likely.
+var maxLetterByte = byte(256 - (256 % len(letterBytes)))
+
+// entropyPool buffers reads from an entropy source and hands out bias-free random characters from
+// letterBytes. It is safe for concurrent use by multiple goroutines.
+type entropyPool struct {
+	mu     sync.Mutex
+	source io.Reader
+	buf    []byte
+}
+
+// Option configures the package-level entropy source used by New.
+type Option func(*entropyPool)
+
+// WithRandSource overrides the entropy source used to generate the random suffix of an ID. It is
+// primarily intended for tests that need deterministic, reproducible output.
+func WithRandSource(source io.Reader) Option {
+	return func(p *entropyPool) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.source = source
+		p.buf = nil
+	}
+}
+
+// newEntropyPool returns an entropyPool reading from crypto/rand by default.
+func newEntropyPool() *entropyPool {
+	return &entropyPool{source: cryptorand.Reader}
+}
+
+// Configure applies opts to the package-level entropy source used by New.
+func Configure(opts ...Option) {
+	for _, opt := range opts {
+		opt(defaultPool)
+	}
+}
+
+// next returns n random characters drawn from letterBytes, reading fresh entropy in blocks and
+// discarding biased bytes via rejection sampling. It is safe to call concurrently.
+func (p *entropyPool) next(n int) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]byte, n)
+	for i := 0; i < n; {
+		if len(p.buf) == 0 {
+			block := make([]byte, 64)
+			if _, err := io.ReadFull(p.source, block); err != nil {
+				panic("idgen: failed to read entropy: " + err.Error())
+			}
+			p.buf = block
+		}
+
+		b := p.buf[0]
+		p.buf = p.buf[1:]
+		if b >= maxLetterByte {
+			continue
+		}
+
+		out[i] = letterBytes[b%byte(len(letterBytes))]
+		i++
 	}
-	return string(randBytes)
+
+	return string(out)
+}
+
+// defaultPool is the package-level entropy source used by New.
+var defaultPool = newEntropyPool()
+
+// generates a cryptographically strong random string, safe for concurrent use
+func getRandString(n int) string {
+	return defaultPool.next(n)
 }
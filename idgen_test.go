@@ -1,8 +1,11 @@
 package idgen
 
 import (
+	"bytes"
+	cryptorand "crypto/rand"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -121,3 +124,41 @@ func TestIDValidation(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// TestConfigureWithRandSource tests that Configure/WithRandSource lets getRandString be driven by a
+// deterministic entropy source
+func TestConfigureWithRandSource(t *testing.T) {
+	logging.Write(logging.LevelTest, "Testing Configure with a deterministic entropy source")
+	defer Configure(WithRandSource(cryptorand.Reader))
+
+	Configure(WithRandSource(bytes.NewReader(bytes.Repeat([]byte{0}, 64))))
+
+	want := strings.Repeat(string(letterBytes[0]), 7)
+	if got := getRandString(7); got != want {
+		logging.Write(logging.LevelTestFailure, "getRandString with deterministic zero source did not match expected output")
+		t.Fail()
+	}
+}
+
+// TestGenerateConcurrentSafety tests that concurrent calls to New don't race or produce malformed
+// IDs
+func TestGenerateConcurrentSafety(t *testing.T) {
+	logging.Write(logging.LevelTest, "Testing concurrent New")
+	const goroutines = 50
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			id, err := New(IndicatorEntity, testVendor, testType, testSubType, "")
+			if err != nil || id == "" || len(id) != idLength {
+				logging.Write(logging.LevelTestFailure, "Concurrent New produced an invalid ID: "+id)
+				t.Fail()
+			}
+		}()
+	}
+
+	wg.Wait()
+}